@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// newRequestID gera um identificador curto e aleatório para correlacionar
+// os logs de uma requisição.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestIDMiddleware honra um X-Request-ID enviado pelo cliente (ou gera um
+// novo), propaga-o na resposta e no contexto da requisição, e conta a
+// requisição nas métricas de /metrics.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqID := r.Header.Get("X-Request-ID")
+		if reqID == "" {
+			reqID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", reqID)
+
+		recordRequest(r.URL.Path)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, reqID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// accessLogEntry é a linha de log estruturado (JSON) emitida para cada
+// chamada a /generate.
+type accessLogEntry struct {
+	RequestID         string `json:"request_id"`
+	Device            string `json:"device"`
+	Keyword           string `json:"keyword"`
+	Language          string `json:"language"`
+	UpstreamLatencyMS int64  `json:"upstream_latency_ms"`
+	PromptTokens      int    `json:"prompt_tokens"`
+	CompletionTokens  int    `json:"completion_tokens"`
+	TotalTokens       int    `json:"total_tokens"`
+	Status            int    `json:"status"`
+	CacheHit          bool   `json:"cache_hit"`
+}
+
+// logAccess grava entry como uma linha JSON, para consumo por qualquer
+// coletor de logs estruturados.
+func logAccess(entry accessLogEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	log.Println(string(b))
+}
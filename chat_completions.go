@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// ChatMessage representa uma mensagem no formato padrão da OpenAI (role/content).
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatCompletionRequest espelha o corpo aceito por /v1/chat/completions da
+// OpenAI, permitindo que qualquer SDK compatível aponte para este gateway
+// como um proxy drop-in. O Model aceita o mesmo prefixo de provider usado em
+// GenerateRequest (ex. "azure/gpt-4o", "anthropic/claude-3-5-sonnet",
+// "local/llama3") ou um alias de models.yaml — sem prefixo ou alias, usa a
+// Chat Completions API da OpenAI diretamente.
+type ChatCompletionRequest struct {
+	Model       string          `json:"model"`
+	Messages    []ChatMessage   `json:"messages"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	TopP        *float64        `json:"top_p,omitempty"`
+	MaxTokens   *int            `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+	Tools       json.RawMessage `json:"tools,omitempty"`
+	ToolChoice  json.RawMessage `json:"tool_choice,omitempty"`
+}
+
+// chatCompletionMessageOut e chatCompletionChoiceOut compõem
+// chatCompletionResponseOut — ver abaixo.
+type chatCompletionMessageOut struct {
+	Role      string                   `json:"role"`
+	Content   string                   `json:"content,omitempty"`
+	ToolCalls []map[string]interface{} `json:"tool_calls,omitempty"`
+}
+
+type chatCompletionChoiceOut struct {
+	Index        int                      `json:"index"`
+	Message      chatCompletionMessageOut `json:"message"`
+	FinishReason string                   `json:"finish_reason"`
+}
+
+// chatCompletionResponseOut é a forma devolvida por /v1/chat/completions,
+// montada a partir do ProviderResponse canônico — assim a resposta tem
+// sempre o mesmo formato para o cliente, não importa qual provider a
+// atendeu de fato.
+type chatCompletionResponseOut struct {
+	ID      string                    `json:"id"`
+	Object  string                    `json:"object"`
+	Model   string                    `json:"model"`
+	Choices []chatCompletionChoiceOut `json:"choices"`
+	Usage   struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// resolveChatProvider resolve o provider para /v1/chat/completions: os
+// mesmos prefixos e aliases de /generate, mas caindo em "openai-chat" (a
+// própria Chat Completions API) em vez do provider padrão de /generate
+// ("openai", a Responses API), já que este endpoint fala o wire format de
+// chat completions nativamente.
+func resolveChatProvider(model string) (Provider, string, error) {
+	provider, resolvedModel, err := resolveProvider(model, "")
+	if err != nil {
+		return nil, "", err
+	}
+	if provider == providerRegistry[defaultProviderName] {
+		provider = providerRegistry["openai-chat"]
+	}
+	return provider, resolvedModel, nil
+}
+
+// chatCompletionsHandler expõe /v1/chat/completions no formato padrão da
+// OpenAI, roteando através do mesmo providerRegistry usado por /generate —
+// incluindo o proxy pool e o suporte a Azure, Anthropic e backends locais —
+// e repassando o resultado com streaming via Server-Sent Events quando
+// `stream: true`.
+func chatCompletionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Messages) == 0 {
+		http.Error(w, "messages é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	provider, model, err := resolveChatProvider(req.Model)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items := make([]OpenAIInputItem, len(req.Messages))
+	for i, m := range req.Messages {
+		items[i] = OpenAIInputItem{Role: m.Role, Content: m.Content}
+	}
+
+	providerReq := ProviderRequest{Model: model, Items: items, Tools: req.Tools, ToolChoice: req.ToolChoice}
+
+	if req.Stream {
+		streamChatCompletion(r.Context(), w, provider, providerReq)
+		return
+	}
+
+	resp, err := provider.Generate(r.Context(), providerReq)
+	if err != nil {
+		http.Error(w, "Erro no provider: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	choice := chatCompletionChoiceOut{
+		Message:      chatCompletionMessageOut{Role: "assistant", Content: resp.Output},
+		FinishReason: resp.FinishReason,
+	}
+	for id, tc := range resp.ToolCalls {
+		choice.Message.ToolCalls = append(choice.Message.ToolCalls, map[string]interface{}{
+			"id":   id,
+			"type": "function",
+			"function": map[string]string{
+				"name":      tc.Name,
+				"arguments": tc.Arguments,
+			},
+		})
+	}
+
+	out := chatCompletionResponseOut{ID: "chatcmpl-" + newRequestID(), Object: "chat.completion", Model: req.Model, Choices: []chatCompletionChoiceOut{choice}}
+	out.Usage.PromptTokens = resp.Usage.Prompt
+	out.Usage.CompletionTokens = resp.Usage.Completion
+	out.Usage.TotalTokens = resp.Usage.Total
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// streamChatCompletion repassa a resposta SSE do provider resolvido
+// diretamente ao cliente — os providers no wire format de chat completions
+// (openai-chat, azure, local) já produzem eventos no formato esperado pelo
+// cliente, sem necessidade de reconversão. ctx é o contexto da requisição
+// recebida, para que a desconexão do cliente cancele a chamada upstream.
+func streamChatCompletion(ctx context.Context, w http.ResponseWriter, provider Provider, providerReq ProviderRequest) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming não suportado", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if err := provider.Stream(ctx, providerReq, w, flusher.Flush); err != nil {
+		_, _ = w.Write([]byte("data: {\"error\":\"" + err.Error() + "\"}\n\n"))
+		flusher.Flush()
+	}
+}
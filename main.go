@@ -1,15 +1,13 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
-	"strings"
-	"sync"
+	"time"
 )
 
 var (
@@ -26,6 +24,23 @@ type GenerateRequest struct {
 	Language   string `json:"language"`
 	Prompt     string `json:"prompt"`
 	Refresh    bool   `json:"refresh"`
+
+	// Model aceita um prefixo de provider (ex. "azure/gpt-4o",
+	// "anthropic/claude-3-5-sonnet", "local/llama3") ou um alias definido em
+	// models.yaml. Vazio usa o provider e modelo padrão.
+	Model string `json:"model,omitempty"`
+	// Provider força o provider a ser usado, ignorando o prefixo de Model.
+	Provider string `json:"provider,omitempty"`
+
+	// Tools e ToolChoice são repassados como estão, sem conversão, ao
+	// provider resolvido (ver provider.go); o provider anthropic não os envia.
+	Tools      json.RawMessage `json:"tools,omitempty"`
+	ToolChoice json.RawMessage `json:"tool_choice,omitempty"`
+
+	// ToolResults fecha o loop de uma chamada de ferramenta pendente:
+	// mapeia o call_id devolvido em ToolCalls para a saída produzida pelo
+	// dispositivo, que é anexada ao histórico antes da próxima chamada.
+	ToolResults map[string]string `json:"tool_results,omitempty"`
 }
 
 type CodeResponse struct {
@@ -34,34 +49,71 @@ type CodeResponse struct {
 	Language   string `json:"language"`
 	Prompt     string `json:"prompt"`
 	Output     string `json:"output"`
+
+	ToolCalls    map[string]ToolCall `json:"tool_calls,omitempty"`
+	FinishReason string              `json:"finish_reason,omitempty"`
+	TokenUsage   TokenUsage          `json:"token_usage,omitempty"`
 }
 
-type OpenAIRequest struct {
-	Model string `json:"model"`
-	Input string `json:"input"`
+// ToolCall é uma chamada de função solicitada pelo modelo, análoga ao
+// ToolCalls carregado por ai.InvokeResponse no yomo.
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
-type OpenAIResponse struct {
-	Output []struct {
-		Content []struct {
-			Text string `json:"text"`
-		} `json:"content"`
-	} `json:"output"`
+// TokenUsage reflete o consumo de tokens reportado pela OpenAI para a chamada.
+type TokenUsage struct {
+	Prompt     int `json:"prompt"`
+	Completion int `json:"completion"`
+	Total      int `json:"total"`
 }
 
-// Banco em memória
-var (
-	mu    sync.RWMutex
-	store = make(map[string]CodeResponse)
-)
+// OpenAIInputItem é um item do histórico de turnos enviado como `input` para
+// a Responses API: mensagens de usuário/assistente, chamadas de função
+// (`function_call`) e seus resultados (`function_call_output`). É também o
+// formato canônico de turno usado internamente para rotear entre providers.
+type OpenAIInputItem struct {
+	Type      string `json:"type,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Content   string `json:"content,omitempty"`
+	CallID    string `json:"call_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Output    string `json:"output,omitempty"`
+}
+
+// store é o backend de persistência do cache de respostas por dispositivo,
+// selecionado via STORE_BACKEND (memory, file ou redis).
+var store Store
+
+// proxyPool distribui as chamadas a callOpenAI entre os proxies listados em
+// PROXY_POOL_FILE, quando configurado.
+var proxyPool *ProxyPool
 
 func main() {
 	if serviceAPIKey == "" {
 		log.Fatal("Variável de ambiente 'SERVICE_API_KEY' não definida.")
 	}
 
-	http.Handle("/generate", authMiddleware(http.HandlerFunc(generateHandler)))
-	http.Handle("/code", authMiddleware(http.HandlerFunc(codeHandler)))
+	s, err := newStoreFromEnv()
+	if err != nil {
+		log.Fatalf("Erro ao inicializar store: %v", err)
+	}
+	store = s
+
+	pool, err := newProxyPoolFromEnv()
+	if err != nil {
+		log.Fatalf("Erro ao inicializar proxy pool: %v", err)
+	}
+	proxyPool = pool
+
+	http.Handle("/generate", requestIDMiddleware(authMiddleware(rateLimitMiddleware(http.HandlerFunc(generateHandler), true))))
+	http.Handle("/code", requestIDMiddleware(authMiddleware(rateLimitMiddleware(http.HandlerFunc(codeHandler), false))))
+	http.Handle("/v1/chat/completions", requestIDMiddleware(authMiddleware(rateLimitMiddleware(http.HandlerFunc(chatCompletionsHandler), false))))
+	http.Handle("/proxies", requestIDMiddleware(authMiddleware(rateLimitMiddleware(http.HandlerFunc(proxiesHandler), false))))
+	http.HandleFunc("/metrics", metricsHandler)
 
 	fmt.Println("Servidor rodando em http://localhost:8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
@@ -81,63 +133,151 @@ func authMiddleware(next http.Handler) http.Handler {
 
 // 1️⃣ /generate → gera/atualiza código
 func generateHandler(w http.ResponseWriter, r *http.Request) {
+	// upstreamLatencyMS é o tempo gasto na chamada ao provider; fica 0 quando
+	// a resposta vem do cache ou a requisição falha antes dessa chamada.
+	var upstreamLatencyMS int64
+
+	var req GenerateRequest
+
+	// fail responde com o erro e registra o log de acesso com o status real
+	// da falha no ponto em que ela ocorre — diferente do caminho de sucesso
+	// abaixo, que loga no fim.
+	fail := func(status int, msg string) {
+		http.Error(w, msg, status)
+		logAccess(accessLogEntry{
+			RequestID:         requestIDFromContext(r.Context()),
+			Device:            req.DeviceName,
+			Keyword:           req.Keyword,
+			Language:          req.Language,
+			UpstreamLatencyMS: upstreamLatencyMS,
+			Status:            status,
+		})
+	}
+
 	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		fail(http.StatusMethodNotAllowed, "Método não permitido")
 		return
 	}
 
-	var req GenerateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		fail(http.StatusBadRequest, "JSON inválido")
 		return
 	}
 
 	if req.DeviceName == "" || req.Keyword == "" || req.Language == "" || req.Prompt == "" {
-		http.Error(w, "device_name, keyword, language e prompt são obrigatórios", http.StatusBadRequest)
+		fail(http.StatusBadRequest, "device_name, keyword, language e prompt são obrigatórios")
 		return
 	}
 
-	mu.RLock()
-	entry, exists := store[req.DeviceName]
-	mu.RUnlock()
+	entry, exists, err := store.Get(req.DeviceName)
+	if err != nil {
+		fail(http.StatusInternalServerError, "Erro ao consultar store: "+err.Error())
+		return
+	}
+	// Uma chamada com tool_results precisa sempre fechar o loop junto ao
+	// provider, mesmo com uma entrada em cache — caso contrário o resultado
+	// da ferramenta enviado pelo dispositivo nunca chegaria ao histórico.
+	hasToolResults := len(req.ToolResults) > 0
+	cacheHit := exists && !req.Refresh && !hasToolResults
 
-	if !exists || req.Refresh {
+	if !exists || req.Refresh || hasToolResults {
 		fmt.Println("Chamando API ChatGPT...")
-		output, err := callOpenAI(req.Prompt)
+
+		items, err := store.GetHistory(req.DeviceName)
+		if err != nil {
+			fail(http.StatusInternalServerError, "Erro ao consultar histórico: "+err.Error())
+			return
+		}
+
+		for callID, output := range req.ToolResults {
+			items = append(items, OpenAIInputItem{Type: "function_call_output", CallID: callID, Output: output})
+		}
+		items = append(items, OpenAIInputItem{Role: "user", Content: req.Prompt})
+
+		upstreamStart := time.Now()
+		output, toolCalls, finishReason, usage, err := callOpenAI(req.Model, req.Provider, items, req.Tools, req.ToolChoice)
+		upstreamLatencyMS = time.Since(upstreamStart).Milliseconds()
 		if err != nil {
-			http.Error(w, "Erro OpenAI: "+err.Error(), http.StatusInternalServerError)
+			fail(http.StatusInternalServerError, "Erro OpenAI: "+err.Error())
+			return
+		}
+
+		if output != "" {
+			items = append(items, OpenAIInputItem{Role: "assistant", Content: output})
+		}
+		for callID, tc := range toolCalls {
+			items = append(items, OpenAIInputItem{Type: "function_call", CallID: callID, Name: tc.Name, Arguments: tc.Arguments})
+		}
+
+		if err := store.PutHistory(req.DeviceName, items); err != nil {
+			fail(http.StatusInternalServerError, "Erro ao gravar histórico: "+err.Error())
 			return
 		}
 
 		entry = CodeResponse{
-			DeviceName: req.DeviceName,
-			Keyword:    req.Keyword,
-			Language:   req.Language,
-			Prompt:     req.Prompt,
-			Output:     output,
+			DeviceName:   req.DeviceName,
+			Keyword:      req.Keyword,
+			Language:     req.Language,
+			Prompt:       req.Prompt,
+			Output:       output,
+			ToolCalls:    toolCalls,
+			FinishReason: finishReason,
+			TokenUsage:   usage,
+		}
+
+		if err := store.Put(req.DeviceName, entry); err != nil {
+			fail(http.StatusInternalServerError, "Erro ao gravar store: "+err.Error())
+			return
 		}
+	}
 
-		mu.Lock()
-		store[req.DeviceName] = entry
-		mu.Unlock()
+	if cacheHit {
+		recordCacheHit()
 	}
 
+	logAccess(accessLogEntry{
+		RequestID:         requestIDFromContext(r.Context()),
+		Device:            req.DeviceName,
+		Keyword:           req.Keyword,
+		Language:          req.Language,
+		UpstreamLatencyMS: upstreamLatencyMS,
+		PromptTokens:      entry.TokenUsage.Prompt,
+		CompletionTokens:  entry.TokenUsage.Completion,
+		TotalTokens:       entry.TokenUsage.Total,
+		Status:            http.StatusOK,
+		CacheHit:          cacheHit,
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(entry)
 }
 
-// 2️⃣ /code → busca código salvo
+// 2️⃣ /code → busca código salvo. Com ?all=true lista todas as entradas do
+// store (opcionalmente filtradas por ?filter=), em vez de uma só.
 func codeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("all") == "true" {
+		entries, err := store.List(r.URL.Query().Get("filter"))
+		if err != nil {
+			http.Error(w, "Erro ao listar store: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries)
+		return
+	}
+
 	device := r.URL.Query().Get("device")
 	if device == "" {
 		http.Error(w, "device é obrigatório", http.StatusBadRequest)
 		return
 	}
 
-	mu.RLock()
-	entry, exists := store[device]
-	mu.RUnlock()
-
+	entry, exists, err := store.Get(device)
+	if err != nil {
+		http.Error(w, "Erro ao consultar store: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 	if !exists {
 		http.Error(w, "device não encontrado", http.StatusNotFound)
 		return
@@ -147,37 +287,28 @@ func codeHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(entry)
 }
 
-// --- Integração com OpenAI ---
-func callOpenAI(prompt string) (string, error) {
-	reqBody := OpenAIRequest{Model: "o4-mini", Input: prompt}
-	bodyBytes, _ := json.Marshal(reqBody)
-
-	client := &http.Client{}
-	req, _ := http.NewRequest("POST", "https://api.openai.com/v1/responses", bytes.NewBuffer(bodyBytes))
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
+// --- Integração com providers ---
 
-	if apiOrg != "" {
-		req.Header.Set("OpenAI-Organization", apiOrg)
-	}
-	if apiProject != "" {
-		req.Header.Set("OpenAI-Project", apiProject)
-	}
-
-	resp, err := client.Do(req)
+// callOpenAI resolve o provider a ser usado (a partir do prefixo de model ou
+// de um override explícito), envia o histórico de turnos e devolve o texto
+// gerado, as chamadas de função solicitadas, o motivo de finalização e o
+// consumo de tokens. Apesar do nome histórico, hoje é apenas um despachante
+// fino sobre o providerRegistry — veja provider.go.
+func callOpenAI(model, providerOverride string, items []OpenAIInputItem, tools, toolChoice json.RawMessage) (string, map[string]ToolCall, string, TokenUsage, error) {
+	provider, resolvedModel, err := resolveProvider(model, providerOverride)
 	if err != nil {
-		return "", err
+		return "", nil, "", TokenUsage{}, err
 	}
-	defer resp.Body.Close()
-
-	b, _ := io.ReadAll(resp.Body)
 
-	var oaResp OpenAIResponse
-	if err := json.Unmarshal(b, &oaResp); err == nil {
-		if len(oaResp.Output) > 0 && len(oaResp.Output[0].Content) > 0 {
-			return strings.TrimSpace(oaResp.Output[0].Content[0].Text), nil
-		}
+	resp, err := provider.Generate(context.Background(), ProviderRequest{
+		Model:      resolvedModel,
+		Items:      items,
+		Tools:      tools,
+		ToolChoice: toolChoice,
+	})
+	if err != nil {
+		return "", nil, "", TokenUsage{}, err
 	}
 
-	return string(b), nil
+	return resp.Output, resp.ToolCalls, resp.FinishReason, resp.Usage, nil
 }
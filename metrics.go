@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// metricsBuckets são os limites superiores (em segundos) do histograma de
+// latência upstream exposto em /metrics.
+var metricsBuckets = []float64{0.1, 0.25, 0.5, 1, 2, 5, 10}
+
+// histogram é um histograma cumulativo no mesmo formato exposto pelo
+// cliente oficial de Prometheus, implementado aqui para não depender de uma
+// biblioteca externa.
+type histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []int64
+	sum     float64
+	count   int64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]int64, len(buckets))}
+}
+
+func (h *histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sum += v
+	h.count++
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+}
+
+// histogramSnapshot é uma cópia consistente do estado de um histogram, segura
+// para ser lida fora de h.mu.
+type histogramSnapshot struct {
+	buckets []float64
+	counts  []int64
+	sum     float64
+	count   int64
+}
+
+// Snapshot copia o estado atual do histograma sob h.mu, para que quem expõe
+// as métricas (metricsHandler) nunca leia buckets/counts/sum/count enquanto
+// Observe os está mutando.
+func (h *histogram) Snapshot() histogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return histogramSnapshot{
+		buckets: append([]float64{}, h.buckets...),
+		counts:  append([]int64{}, h.counts...),
+		sum:     h.sum,
+		count:   h.count,
+	}
+}
+
+var (
+	metricsMu           sync.Mutex
+	requestsTotal       = map[string]int64{}
+	cacheHitsTotal      int64
+	openAICallsTotal    int64
+	errorsByStatusTotal = map[int]int64{}
+	upstreamLatency     = newHistogram(metricsBuckets)
+)
+
+func recordRequest(path string) {
+	metricsMu.Lock()
+	requestsTotal[path]++
+	metricsMu.Unlock()
+}
+
+func recordCacheHit() {
+	metricsMu.Lock()
+	cacheHitsTotal++
+	metricsMu.Unlock()
+}
+
+func recordOpenAICall() {
+	metricsMu.Lock()
+	openAICallsTotal++
+	metricsMu.Unlock()
+}
+
+func recordUpstreamError(status int) {
+	metricsMu.Lock()
+	errorsByStatusTotal[status]++
+	metricsMu.Unlock()
+}
+
+func recordUpstreamLatency(seconds float64) {
+	upstreamLatency.Observe(seconds)
+}
+
+// instrumentedDo executa req com client medindo a latência upstream,
+// contando a chamada e registrando erros por status HTTP — usado por todos
+// os providers no lugar de client.Do diretamente.
+func instrumentedDo(client *http.Client, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := client.Do(req)
+	recordUpstreamLatency(time.Since(start).Seconds())
+	recordOpenAICall()
+
+	if err != nil {
+		recordUpstreamError(0)
+		return resp, err
+	}
+	if resp.StatusCode >= 400 {
+		recordUpstreamError(resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// metricsHandler expõe as métricas no formato de texto do Prometheus.
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP gpt_gateway_requests_total Total de requisições recebidas, por rota.")
+	fmt.Fprintln(w, "# TYPE gpt_gateway_requests_total counter")
+	for path, n := range requestsTotal {
+		fmt.Fprintf(w, "gpt_gateway_requests_total{path=%q} %d\n", path, n)
+	}
+
+	fmt.Fprintln(w, "# HELP gpt_gateway_cache_hits_total Respostas de /generate servidas do cache, sem chamar o provider.")
+	fmt.Fprintln(w, "# TYPE gpt_gateway_cache_hits_total counter")
+	fmt.Fprintf(w, "gpt_gateway_cache_hits_total %d\n", cacheHitsTotal)
+
+	fmt.Fprintln(w, "# HELP gpt_gateway_provider_calls_total Chamadas realizadas a um provider upstream.")
+	fmt.Fprintln(w, "# TYPE gpt_gateway_provider_calls_total counter")
+	fmt.Fprintf(w, "gpt_gateway_provider_calls_total %d\n", openAICallsTotal)
+
+	fmt.Fprintln(w, "# HELP gpt_gateway_provider_errors_total Erros por status HTTP retornado pelo provider upstream.")
+	fmt.Fprintln(w, "# TYPE gpt_gateway_provider_errors_total counter")
+	for status, n := range errorsByStatusTotal {
+		fmt.Fprintf(w, "gpt_gateway_provider_errors_total{status=\"%d\"} %d\n", status, n)
+	}
+
+	snap := upstreamLatency.Snapshot()
+	fmt.Fprintln(w, "# HELP gpt_gateway_provider_latency_seconds Latência das chamadas ao provider upstream.")
+	fmt.Fprintln(w, "# TYPE gpt_gateway_provider_latency_seconds histogram")
+	for i, b := range snap.buckets {
+		fmt.Fprintf(w, "gpt_gateway_provider_latency_seconds_bucket{le=\"%g\"} %d\n", b, snap.counts[i])
+	}
+	fmt.Fprintf(w, "gpt_gateway_provider_latency_seconds_bucket{le=\"+Inf\"} %d\n", snap.count)
+	fmt.Fprintf(w, "gpt_gateway_provider_latency_seconds_sum %f\n", snap.sum)
+	fmt.Fprintf(w, "gpt_gateway_provider_latency_seconds_count %d\n", snap.count)
+}
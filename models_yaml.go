@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// modelConfigEntry é a configuração de um alias de modelo em models.yaml.
+// Provider e Model são lidos por resolveProvider apenas para aliases sem
+// prefixo de provider registrado; um alias como "azure/gpt-4o" é roteado
+// pelo prefixo. BaseURL, APIVersion e Deployment são lidos apenas pelo
+// provider azure, na entrada "azure/<modelo>"; os demais providers os ignoram.
+type modelConfigEntry struct {
+	Provider   string
+	Model      string
+	BaseURL    string
+	APIVersion string
+	Deployment string
+}
+
+func (c modelConfigEntry) modelOrAlias(alias string) string {
+	if c.Model != "" {
+		return c.Model
+	}
+	return alias
+}
+
+// modelsConfig mapeia um alias de modelo para a configuração de provider
+// carregada de MODELS_CONFIG_FILE (models.yaml).
+var modelsConfig = loadModelsConfig(os.Getenv("MODELS_CONFIG_FILE"))
+
+// loadModelsConfig interpreta o subconjunto de YAML usado por models.yaml:
+//
+//	models:
+//	  azure/gpt-4o:
+//	    provider: azure
+//	    model: gpt-4o
+//	    base_url: https://my-resource.openai.azure.com
+//	    api_version: 2024-05-01-preview
+//
+// Não há dependência de uma biblioteca de YAML completa: apenas a forma de
+// chave/valor indentada usada aqui é suportada.
+func loadModelsConfig(path string) map[string]modelConfigEntry {
+	cfg := make(map[string]modelConfigEntry)
+	if path == "" {
+		return cfg
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg
+	}
+	defer f.Close()
+
+	var currentAlias string
+	var entry modelConfigEntry
+	inModels := false
+
+	flush := func() {
+		if currentAlias != "" {
+			cfg[currentAlias] = entry
+		}
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " ")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		switch {
+		case indent == 0 && trimmed == "models:":
+			inModels = true
+		case inModels && indent == 2 && strings.HasSuffix(trimmed, ":"):
+			flush()
+			currentAlias = strings.TrimSuffix(trimmed, ":")
+			entry = modelConfigEntry{}
+		case inModels && indent >= 4 && strings.Contains(trimmed, ":"):
+			key, value, _ := strings.Cut(trimmed, ":")
+			key = strings.TrimSpace(key)
+			value = strings.Trim(strings.TrimSpace(value), `"'`)
+			switch key {
+			case "provider":
+				entry.Provider = value
+			case "model":
+				entry.Model = value
+			case "base_url":
+				entry.BaseURL = value
+			case "api_version":
+				entry.APIVersion = value
+			case "deployment":
+				entry.Deployment = value
+			}
+		}
+	}
+	flush()
+
+	return cfg
+}
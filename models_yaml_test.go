@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadModelsConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "models.yaml")
+
+	yaml := "models:\n" +
+		"  azure/gpt-4o:\n" +
+		"    provider: azure\n" +
+		"    model: gpt-4o\n" +
+		"    base_url: https://my-resource.openai.azure.com\n" +
+		"    api_version: 2024-05-01-preview\n" +
+		"  local/llama3:\n" +
+		"    provider: local\n"
+
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("erro ao escrever models.yaml de teste: %v", err)
+	}
+
+	cfg := loadModelsConfig(path)
+
+	azure, ok := cfg["azure/gpt-4o"]
+	if !ok {
+		t.Fatalf("esperava entrada azure/gpt-4o em %+v", cfg)
+	}
+	if azure.Provider != "azure" || azure.Model != "gpt-4o" || azure.BaseURL != "https://my-resource.openai.azure.com" || azure.APIVersion != "2024-05-01-preview" {
+		t.Errorf("entrada azure/gpt-4o incorreta: %+v", azure)
+	}
+
+	local, ok := cfg["local/llama3"]
+	if !ok {
+		t.Fatalf("esperava entrada local/llama3 em %+v", cfg)
+	}
+	if local.Provider != "local" || local.modelOrAlias("local/llama3") != "local/llama3" {
+		t.Errorf("entrada local/llama3 incorreta: %+v", local)
+	}
+}
+
+func TestLoadModelsConfigMissingFile(t *testing.T) {
+	cfg := loadModelsConfig("/caminho/que/nao/existe.yaml")
+	if len(cfg) != 0 {
+		t.Errorf("esperava config vazia para arquivo inexistente, obtive %+v", cfg)
+	}
+}
+
+func TestLoadModelsConfigEmptyPath(t *testing.T) {
+	cfg := loadModelsConfig("")
+	if len(cfg) != 0 {
+		t.Errorf("esperava config vazia para path vazio, obtive %+v", cfg)
+	}
+}
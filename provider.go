@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ProviderRequest é o pedido canônico enviado a qualquer Provider: o
+// histórico de turnos no formato interno do gateway, as ferramentas
+// disponíveis e o modelo já resolvido (sem o prefixo de provider).
+type ProviderRequest struct {
+	Model      string
+	Items      []OpenAIInputItem
+	Tools      json.RawMessage
+	ToolChoice json.RawMessage
+}
+
+// ProviderResponse é o resultado canônico devolvido por qualquer Provider.
+type ProviderResponse struct {
+	Output       string
+	ToolCalls    map[string]ToolCall
+	FinishReason string
+	Usage        TokenUsage
+}
+
+// Provider abstrai um backend compatível com a OpenAI para o qual o gateway
+// pode rotear uma requisição: OpenAI, Azure OpenAI, Anthropic ou um backend
+// local compatível (Ollama, vLLM, etc).
+type Provider interface {
+	Generate(ctx context.Context, req ProviderRequest) (ProviderResponse, error)
+	Stream(ctx context.Context, req ProviderRequest, w io.Writer, flush func()) error
+}
+
+// defaultProviderName é usado quando o modelo não tem prefixo de provider e
+// não há alias correspondente em models.yaml.
+const defaultProviderName = "openai"
+
+// providerRegistry mapeia um nome de provider (o prefixo em "azure/gpt-4o",
+// por exemplo) para sua implementação.
+var providerRegistry = map[string]Provider{
+	defaultProviderName: &openAIResponsesProvider{},
+	"openai-chat":       &openAIChatProvider{},
+	"azure":             &azureProvider{},
+	"anthropic":         &anthropicProvider{},
+	"local":             &localProvider{},
+}
+
+// resolveProvider decide qual Provider e qual nome de modelo usar para uma
+// requisição, nesta ordem de prioridade:
+//  1. override explícito (ex. GenerateRequest.Provider)
+//  2. prefixo do modelo, ex. "azure/gpt-4o" → provider "azure", modelo "gpt-4o"
+//  3. alias definido em models.yaml
+//  4. provider padrão (OpenAI Responses API) com o modelo como veio
+func resolveProvider(model, override string) (Provider, string, error) {
+	if override != "" {
+		p, ok := providerRegistry[override]
+		if !ok {
+			return nil, "", fmt.Errorf("provider desconhecido: %s", override)
+		}
+		return p, stripProviderPrefix(model), nil
+	}
+
+	if name, rest, ok := strings.Cut(model, "/"); ok {
+		if p, ok := providerRegistry[name]; ok {
+			return p, rest, nil
+		}
+	}
+
+	if cfg, ok := modelsConfig[model]; ok {
+		p, ok := providerRegistry[cfg.Provider]
+		if !ok {
+			return nil, "", fmt.Errorf("provider desconhecido em models.yaml: %s", cfg.Provider)
+		}
+		return p, cfg.modelOrAlias(model), nil
+	}
+
+	return providerRegistry[defaultProviderName], model, nil
+}
+
+func stripProviderPrefix(model string) string {
+	if _, rest, ok := strings.Cut(model, "/"); ok {
+		return rest
+	}
+	return model
+}
+
+// errStreamingUnsupported é devolvido pelo método Stream de providers que
+// ainda não têm streaming implementado.
+func errStreamingUnsupported(provider string) error {
+	return fmt.Errorf("streaming não é suportado pelo provider %s", provider)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// anthropicProvider fala com a Messages API da Anthropic
+// (https://api.anthropic.com/v1/messages).
+type anthropicProvider struct{}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	MaxTokens int                `json:"max_tokens"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// itemsToAnthropicMessages converte o histórico de turnos interno para o
+// formato da Messages API: o papel "system" vira o campo `system` e
+// chamadas/resultados de ferramenta são achatados em mensagens de texto, já
+// que este provider não modela tool use estruturado da Anthropic.
+func itemsToAnthropicMessages(items []OpenAIInputItem) (string, []anthropicMessage) {
+	var system string
+	var messages []anthropicMessage
+
+	for _, item := range items {
+		switch {
+		case item.Type == "function_call":
+			messages = append(messages, anthropicMessage{Role: "assistant", Content: item.Arguments})
+		case item.Type == "function_call_output":
+			messages = append(messages, anthropicMessage{Role: "user", Content: item.Output})
+		case item.Role == "system":
+			system = item.Content
+		default:
+			messages = append(messages, anthropicMessage{Role: item.Role, Content: item.Content})
+		}
+	}
+
+	return system, messages
+}
+
+func (p *anthropicProvider) Generate(ctx context.Context, req ProviderRequest) (ProviderResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = "claude-3-5-sonnet-20241022"
+	}
+	system, messages := itemsToAnthropicMessages(req.Items)
+
+	bodyBytes, err := json.Marshal(anthropicRequest{Model: model, System: system, Messages: messages, MaxTokens: 1024})
+	if err != nil {
+		return ProviderResponse{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return ProviderResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", os.Getenv("ANTHROPIC_API_KEY"))
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+
+	client, proxyAddr := proxyPool.Client()
+	resp, err := instrumentedDo(client, httpReq)
+	proxyPool.RecordResult(proxyAddr, err == nil)
+	if err != nil {
+		return ProviderResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(resp.Body)
+
+	var ar anthropicResponse
+	if err := json.Unmarshal(b, &ar); err != nil {
+		return ProviderResponse{Output: string(b)}, nil
+	}
+
+	var text strings.Builder
+	for _, block := range ar.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	return ProviderResponse{
+		Output:       strings.TrimSpace(text.String()),
+		FinishReason: ar.StopReason,
+		Usage: TokenUsage{
+			Prompt:     ar.Usage.InputTokens,
+			Completion: ar.Usage.OutputTokens,
+			Total:      ar.Usage.InputTokens + ar.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+func (p *anthropicProvider) Stream(ctx context.Context, req ProviderRequest, w io.Writer, flush func()) error {
+	return errStreamingUnsupported("anthropic")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// azureProvider fala com um deployment do Azure OpenAI. O wire format do
+// corpo é o mesmo da Chat Completions API, mas a URL carrega o nome do
+// deployment e a api-version, e a autenticação usa o cabeçalho `api-key` em
+// vez de `Authorization: Bearer`.
+type azureProvider struct{}
+
+// endpoint resolve a URL e o nome de deployment para um modelo, priorizando
+// o que estiver configurado em models.yaml sobre as variáveis de ambiente
+// AZURE_OPENAI_RESOURCE / AZURE_OPENAI_API_VERSION.
+func (p *azureProvider) endpoint(model string) (string, string) {
+	resource := os.Getenv("AZURE_OPENAI_RESOURCE")
+	apiVersion := os.Getenv("AZURE_OPENAI_API_VERSION")
+	if apiVersion == "" {
+		apiVersion = "2024-05-01-preview"
+	}
+
+	deployment := model
+	if cfg, ok := modelsConfig["azure/"+model]; ok {
+		if cfg.Deployment != "" {
+			deployment = cfg.Deployment
+		}
+		if cfg.BaseURL != "" {
+			resource = cfg.BaseURL
+		}
+		if cfg.APIVersion != "" {
+			apiVersion = cfg.APIVersion
+		}
+	}
+
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", resource, deployment, apiVersion), deployment
+}
+
+func (p *azureProvider) headers() map[string]string {
+	return map[string]string{"api-key": os.Getenv("AZURE_OPENAI_API_KEY")}
+}
+
+func (p *azureProvider) Generate(ctx context.Context, req ProviderRequest) (ProviderResponse, error) {
+	url, deployment := p.endpoint(req.Model)
+
+	resp, err := chatCompletionsCall(ctx, url, p.headers(), deployment, req.Items, req.Tools, req.ToolChoice, false)
+	if err != nil {
+		return ProviderResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	return parseChatCompletionResponse(resp.Body)
+}
+
+func (p *azureProvider) Stream(ctx context.Context, req ProviderRequest, w io.Writer, flush func()) error {
+	url, deployment := p.endpoint(req.Model)
+
+	resp, err := chatCompletionsCall(ctx, url, p.headers(), deployment, req.Items, req.Tools, req.ToolChoice, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return relaySSE(resp.Body, w, flush)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// localProvider fala com um backend local compatível com a API de chat da
+// OpenAI (Ollama em modo de compatibilidade, vLLM, LM Studio, etc),
+// endereçado via LOCAL_PROVIDER_BASE_URL.
+type localProvider struct{}
+
+func (p *localProvider) baseURL() string {
+	if u := os.Getenv("LOCAL_PROVIDER_BASE_URL"); u != "" {
+		return u
+	}
+	return "http://localhost:11434/v1"
+}
+
+func (p *localProvider) Generate(ctx context.Context, req ProviderRequest) (ProviderResponse, error) {
+	resp, err := chatCompletionsCall(ctx, p.baseURL()+"/chat/completions", nil, req.Model, req.Items, req.Tools, req.ToolChoice, false)
+	if err != nil {
+		return ProviderResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	return parseChatCompletionResponse(resp.Body)
+}
+
+func (p *localProvider) Stream(ctx context.Context, req ProviderRequest, w io.Writer, flush func()) error {
+	resp, err := chatCompletionsCall(ctx, p.baseURL()+"/chat/completions", nil, req.Model, req.Items, req.Tools, req.ToolChoice, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return relaySSE(resp.Body, w, flush)
+}
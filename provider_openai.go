@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// --- OpenAI Responses API ---
+
+// openAIResponsesProvider chama a Responses API da OpenAI — o comportamento
+// original deste gateway antes da introdução do Provider.
+type openAIResponsesProvider struct{}
+
+// OpenAIRequest é o corpo enviado à Responses API.
+type OpenAIRequest struct {
+	Model      string            `json:"model"`
+	Input      []OpenAIInputItem `json:"input"`
+	Tools      json.RawMessage   `json:"tools,omitempty"`
+	ToolChoice json.RawMessage   `json:"tool_choice,omitempty"`
+}
+
+// OpenAIResponse é a forma relevante da resposta da Responses API.
+type OpenAIResponse struct {
+	Output []struct {
+		Type    string `json:"type"`
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		CallID    string `json:"call_id"`
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"output"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// newOpenAIRequest monta uma requisição HTTP para a API da OpenAI já com os
+// cabeçalhos de autenticação/organização/projeto aplicados.
+func newOpenAIRequest(method, url string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	if apiOrg != "" {
+		req.Header.Set("OpenAI-Organization", apiOrg)
+	}
+	if apiProject != "" {
+		req.Header.Set("OpenAI-Project", apiProject)
+	}
+
+	return req, nil
+}
+
+func (p *openAIResponsesProvider) Generate(ctx context.Context, req ProviderRequest) (ProviderResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = "o4-mini"
+	}
+
+	bodyBytes, _ := json.Marshal(OpenAIRequest{Model: model, Input: req.Items, Tools: req.Tools, ToolChoice: req.ToolChoice})
+
+	httpReq, err := newOpenAIRequest("POST", "https://api.openai.com/v1/responses", bodyBytes)
+	if err != nil {
+		return ProviderResponse{}, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+
+	client, proxyAddr := proxyPool.Client()
+	resp, err := instrumentedDo(client, httpReq)
+	proxyPool.RecordResult(proxyAddr, err == nil)
+	if err != nil {
+		return ProviderResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(resp.Body)
+
+	var oaResp OpenAIResponse
+	if err := json.Unmarshal(b, &oaResp); err != nil {
+		return ProviderResponse{Output: string(b)}, nil
+	}
+
+	out := ProviderResponse{
+		ToolCalls: make(map[string]ToolCall),
+		Usage: TokenUsage{
+			Prompt:     oaResp.Usage.InputTokens,
+			Completion: oaResp.Usage.OutputTokens,
+			Total:      oaResp.Usage.TotalTokens,
+		},
+	}
+
+	for _, item := range oaResp.Output {
+		if item.Type == "function_call" {
+			out.ToolCalls[item.CallID] = ToolCall{ID: item.CallID, Name: item.Name, Arguments: item.Arguments}
+			out.FinishReason = "tool_calls"
+			continue
+		}
+		if len(item.Content) > 0 {
+			out.Output = strings.TrimSpace(item.Content[0].Text)
+			out.FinishReason = "stop"
+		}
+	}
+	if len(out.ToolCalls) == 0 {
+		out.ToolCalls = nil
+	}
+
+	return out, nil
+}
+
+func (p *openAIResponsesProvider) Stream(ctx context.Context, req ProviderRequest, w io.Writer, flush func()) error {
+	return errStreamingUnsupported("openai (responses) — use o provider \"openai-chat\"")
+}
+
+// --- OpenAI Chat Completions API ---
+
+// openAIChatProvider chama /v1/chat/completions da OpenAI diretamente.
+type openAIChatProvider struct{}
+
+func (p *openAIChatProvider) headers() map[string]string {
+	headers := map[string]string{"Authorization": "Bearer " + apiKey}
+	if apiOrg != "" {
+		headers["OpenAI-Organization"] = apiOrg
+	}
+	if apiProject != "" {
+		headers["OpenAI-Project"] = apiProject
+	}
+	return headers
+}
+
+func (p *openAIChatProvider) Generate(ctx context.Context, req ProviderRequest) (ProviderResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = "gpt-4o-mini"
+	}
+
+	resp, err := chatCompletionsCall(ctx, "https://api.openai.com/v1/chat/completions", p.headers(), model, req.Items, req.Tools, req.ToolChoice, false)
+	if err != nil {
+		return ProviderResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	return parseChatCompletionResponse(resp.Body)
+}
+
+func (p *openAIChatProvider) Stream(ctx context.Context, req ProviderRequest, w io.Writer, flush func()) error {
+	model := req.Model
+	if model == "" {
+		model = "gpt-4o-mini"
+	}
+
+	resp, err := chatCompletionsCall(ctx, "https://api.openai.com/v1/chat/completions", p.headers(), model, req.Items, req.Tools, req.ToolChoice, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return relaySSE(resp.Body, w, flush)
+}
+
+// --- helpers compartilhados por providers com wire format de chat completions ---
+
+// chatCompletionResponse é a forma relevante de uma resposta não-streaming
+// de /v1/chat/completions, compartilhada pela OpenAI, Azure OpenAI e
+// backends locais compatíveis.
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// itemsToChatMessages converte o histórico de turnos interno para o formato
+// de mensagens de /v1/chat/completions. Chamadas de função consecutivas (o
+// conjunto de tool calls emitido por uma mesma resposta do modelo) são
+// agrupadas em uma única mensagem `assistant` com um array `tool_calls`, e
+// cada `function_call_output` vira uma mensagem `tool` referenciando o
+// `tool_call_id` correspondente — exigido pelo formato de wire da OpenAI.
+func itemsToChatMessages(items []OpenAIInputItem) []map[string]interface{} {
+	msgs := make([]map[string]interface{}, 0, len(items))
+
+	for i := 0; i < len(items); {
+		item := items[i]
+
+		switch item.Type {
+		case "function_call":
+			var toolCalls []map[string]interface{}
+			for ; i < len(items) && items[i].Type == "function_call"; i++ {
+				toolCalls = append(toolCalls, map[string]interface{}{
+					"id":   items[i].CallID,
+					"type": "function",
+					"function": map[string]string{
+						"name":      items[i].Name,
+						"arguments": items[i].Arguments,
+					},
+				})
+			}
+			msgs = append(msgs, map[string]interface{}{
+				"role":       "assistant",
+				"content":    nil,
+				"tool_calls": toolCalls,
+			})
+
+		case "function_call_output":
+			msgs = append(msgs, map[string]interface{}{
+				"role":         "tool",
+				"tool_call_id": item.CallID,
+				"content":      item.Output,
+			})
+			i++
+
+		default:
+			msgs = append(msgs, map[string]interface{}{"role": item.Role, "content": item.Content})
+			i++
+		}
+	}
+
+	return msgs
+}
+
+// chatCompletionsCall executa uma chamada no formato de
+// /v1/chat/completions contra qualquer provider que fale esse mesmo wire
+// format (OpenAI, Azure OpenAI e backends locais compatíveis como Ollama).
+func chatCompletionsCall(ctx context.Context, url string, headers map[string]string, model string, items []OpenAIInputItem, tools, toolChoice json.RawMessage, stream bool) (*http.Response, error) {
+	body := map[string]interface{}{
+		"model":    model,
+		"messages": itemsToChatMessages(items),
+		"stream":   stream,
+	}
+	if len(tools) > 0 {
+		body["tools"] = tools
+	}
+	if len(toolChoice) > 0 {
+		body["tool_choice"] = toolChoice
+	}
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	client, proxyAddr := proxyPool.Client()
+	resp, err := instrumentedDo(client, httpReq)
+	proxyPool.RecordResult(proxyAddr, err == nil)
+	return resp, err
+}
+
+// parseChatCompletionResponse lê e interpreta uma resposta não-streaming de
+// /v1/chat/completions em um ProviderResponse.
+func parseChatCompletionResponse(body io.Reader) (ProviderResponse, error) {
+	b, _ := io.ReadAll(body)
+
+	var cc chatCompletionResponse
+	if err := json.Unmarshal(b, &cc); err != nil || len(cc.Choices) == 0 {
+		return ProviderResponse{Output: string(b)}, nil
+	}
+
+	choice := cc.Choices[0]
+	out := ProviderResponse{
+		Output:       strings.TrimSpace(choice.Message.Content),
+		FinishReason: choice.FinishReason,
+		Usage: TokenUsage{
+			Prompt:     cc.Usage.PromptTokens,
+			Completion: cc.Usage.CompletionTokens,
+			Total:      cc.Usage.TotalTokens,
+		},
+	}
+	if len(choice.Message.ToolCalls) > 0 {
+		out.ToolCalls = make(map[string]ToolCall)
+		for _, tc := range choice.Message.ToolCalls {
+			out.ToolCalls[tc.ID] = ToolCall{ID: tc.ID, Name: tc.Function.Name, Arguments: tc.Function.Arguments}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestItemsToChatMessagesToolCallRoundTrip(t *testing.T) {
+	items := []OpenAIInputItem{
+		{Role: "user", Content: "qual é o clima em SP?"},
+		{Type: "function_call", CallID: "call_1", Name: "get_weather", Arguments: `{"city":"SP"}`},
+		{Type: "function_call_output", CallID: "call_1", Output: "ensolarado, 28C"},
+	}
+
+	msgs := itemsToChatMessages(items)
+	if len(msgs) != 3 {
+		t.Fatalf("esperava 3 mensagens, obtive %d: %+v", len(msgs), msgs)
+	}
+
+	if msgs[0]["role"] != "user" || msgs[0]["content"] != "qual é o clima em SP?" {
+		t.Errorf("mensagem de usuário incorreta: %+v", msgs[0])
+	}
+
+	assistant := msgs[1]
+	if assistant["role"] != "assistant" {
+		t.Fatalf("esperava role assistant, obtive %+v", assistant)
+	}
+	toolCalls, ok := assistant["tool_calls"].([]map[string]interface{})
+	if !ok || len(toolCalls) != 1 {
+		t.Fatalf("esperava um tool_call, obtive %+v", assistant["tool_calls"])
+	}
+	if toolCalls[0]["id"] != "call_1" || toolCalls[0]["type"] != "function" {
+		t.Errorf("tool_call incorreto: %+v", toolCalls[0])
+	}
+	fn, ok := toolCalls[0]["function"].(map[string]string)
+	if !ok || fn["name"] != "get_weather" || fn["arguments"] != `{"city":"SP"}` {
+		t.Errorf("function do tool_call incorreta: %+v", toolCalls[0]["function"])
+	}
+
+	tool := msgs[2]
+	if tool["role"] != "tool" || tool["tool_call_id"] != "call_1" || tool["content"] != "ensolarado, 28C" {
+		t.Errorf("mensagem tool incorreta: %+v", tool)
+	}
+}
+
+func TestItemsToChatMessagesGroupsConsecutiveToolCalls(t *testing.T) {
+	items := []OpenAIInputItem{
+		{Type: "function_call", CallID: "call_1", Name: "a", Arguments: "{}"},
+		{Type: "function_call", CallID: "call_2", Name: "b", Arguments: "{}"},
+	}
+
+	msgs := itemsToChatMessages(items)
+	if len(msgs) != 1 {
+		t.Fatalf("esperava uma única mensagem assistant agrupando os tool_calls, obtive %d", len(msgs))
+	}
+
+	toolCalls, ok := msgs[0]["tool_calls"].([]map[string]interface{})
+	if !ok || len(toolCalls) != 2 {
+		t.Fatalf("esperava 2 tool_calls agrupados, obtive %+v", msgs[0]["tool_calls"])
+	}
+}
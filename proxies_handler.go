@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// proxiesHandler expõe o estado do ProxyPool (GET) e permite reabilitar
+// manualmente um proxy ejetado (POST ?addr=).
+func proxiesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(proxyPool.Snapshot())
+
+	case http.MethodPost:
+		addr := r.URL.Query().Get("addr")
+		if addr == "" {
+			http.Error(w, "addr é obrigatório", http.StatusBadRequest)
+			return
+		}
+		if !proxyPool.Reenable(addr) {
+			http.Error(w, "proxy não encontrado", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func newTestProxyPool(addrs ...string) *ProxyPool {
+	pool := &ProxyPool{}
+	for _, addr := range addrs {
+		pool.entries = append(pool.entries, &proxyEntry{Addr: addr})
+	}
+	return pool
+}
+
+func TestProxyPoolRecordResultEjectsAfterThreshold(t *testing.T) {
+	pool := newTestProxyPool("proxy-a:8080")
+
+	for i := 0; i < proxyFailureThreshold-1; i++ {
+		pool.RecordResult("proxy-a:8080", false)
+		if pool.entries[0].Ejected {
+			t.Fatalf("proxy não deveria estar ejetado após %d falhas", i+1)
+		}
+	}
+
+	pool.RecordResult("proxy-a:8080", false)
+	if !pool.entries[0].Ejected {
+		t.Fatalf("esperava proxy ejetado após %d falhas consecutivas", proxyFailureThreshold)
+	}
+}
+
+func TestProxyPoolRecordResultSuccessResetsFailures(t *testing.T) {
+	pool := newTestProxyPool("proxy-a:8080")
+
+	pool.RecordResult("proxy-a:8080", false)
+	pool.RecordResult("proxy-a:8080", true)
+
+	if pool.entries[0].Failures != 0 {
+		t.Errorf("esperava contador de falhas zerado após sucesso, obtive %d", pool.entries[0].Failures)
+	}
+}
+
+func TestProxyPoolReenable(t *testing.T) {
+	pool := newTestProxyPool("proxy-a:8080")
+	pool.entries[0].Ejected = true
+	pool.entries[0].Failures = proxyFailureThreshold
+
+	if !pool.Reenable("proxy-a:8080") {
+		t.Fatal("esperava Reenable bem-sucedido para proxy existente")
+	}
+	if pool.entries[0].Ejected || pool.entries[0].Failures != 0 {
+		t.Errorf("esperava proxy reintegrado e sem falhas, obtive %+v", pool.entries[0])
+	}
+
+	if pool.Reenable("proxy-desconhecido:8080") {
+		t.Error("esperava Reenable falhar para endereço desconhecido")
+	}
+}
+
+func TestProxyPoolClientSkipsEjectedEntries(t *testing.T) {
+	pool := newTestProxyPool("proxy-a:8080", "proxy-b:8080")
+	pool.entries[0].Ejected = true
+
+	_, addr := pool.Client()
+	if addr != "proxy-b:8080" {
+		t.Errorf("esperava round-robin pular proxy ejetado e escolher proxy-b:8080, obtive %q", addr)
+	}
+}
+
+func TestProxyPoolClientNilPoolFallsBackToDirectEgress(t *testing.T) {
+	var pool *ProxyPool
+	client, addr := pool.Client()
+	if client == nil || addr != "" {
+		t.Errorf("esperava cliente direto e addr vazio para pool nil, obtive client=%v addr=%q", client, addr)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// rateLimitMiddleware aplica o limite global por X-API-Key e, quando
+// deviceKeyed é true, também o limite por device_name (usado em /generate).
+func rateLimitMiddleware(next http.Handler, deviceKeyed bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if globalRateLimiter != nil {
+			if !globalRateLimiter.Allow(r.Header.Get("X-API-Key")) {
+				http.Error(w, "Limite de requisições excedido", http.StatusTooManyRequests)
+				return
+			}
+		}
+
+		if deviceKeyed && deviceRateLimiter != nil {
+			device := deviceNameFromRequest(r)
+			if device != "" && !deviceRateLimiter.Allow(device) {
+				http.Error(w, "Limite de requisições excedido para o dispositivo", http.StatusTooManyRequests)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// deviceNameFromRequest lê device_name do corpo da requisição sem consumi-lo,
+// devolvendo o body original para o handler seguinte decodificar normalmente.
+func deviceNameFromRequest(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return ""
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var probe struct {
+		DeviceName string `json:"device_name"`
+	}
+	_ = json.Unmarshal(body, &probe)
+	return probe.DeviceName
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// tokenBucket é um limitador de taxa clássico: acumula `rate` tokens por
+// segundo até `capacity`, e cada requisição consome um token.
+type tokenBucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	capacity float64
+	rate     float64
+	last     time.Time
+}
+
+func newTokenBucket(rate, capacity float64) *tokenBucket {
+	return &tokenBucket{tokens: capacity, capacity: capacity, rate: rate, last: time.Now()}
+}
+
+func (b *tokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimiter mantém um tokenBucket por chave (device_name ou X-API-Key),
+// criado sob demanda na primeira requisição vista para aquela chave.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	rate    float64
+	burst   float64
+}
+
+func newRateLimiter(rate, burst float64) *rateLimiter {
+	return &rateLimiter{buckets: make(map[string]*tokenBucket), rate: rate, burst: burst}
+}
+
+func (rl *rateLimiter) Allow(key string) bool {
+	rl.mu.Lock()
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = newTokenBucket(rl.rate, rl.burst)
+		rl.buckets[key] = b
+	}
+	rl.mu.Unlock()
+
+	return b.Allow()
+}
+
+// envFloat lê uma variável de ambiente numérica, devolvendo def se ausente
+// ou inválida.
+func envFloat(name string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// globalRateLimiter aplica RATE_LIMIT_RPS/RATE_LIMIT_BURST por X-API-Key.
+// deviceRateLimiter aplica DEVICE_RATE_LIMIT_RPS/DEVICE_RATE_LIMIT_BURST por
+// device_name, apenas em /generate. RPS <= 0 desativa o respectivo limite.
+var (
+	globalRateLimiter = newOptionalRateLimiter("RATE_LIMIT_RPS", "RATE_LIMIT_BURST")
+	deviceRateLimiter = newOptionalRateLimiter("DEVICE_RATE_LIMIT_RPS", "DEVICE_RATE_LIMIT_BURST")
+)
+
+func newOptionalRateLimiter(rpsVar, burstVar string) *rateLimiter {
+	rps := envFloat(rpsVar, 0)
+	if rps <= 0 {
+		return nil
+	}
+	burst := envFloat(burstVar, rps)
+	return newRateLimiter(rps, burst)
+}
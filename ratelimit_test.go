@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketAllowsUpToCapacity(t *testing.T) {
+	b := newTokenBucket(1, 3)
+
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("esperava requisição %d permitida dentro da capacidade", i+1)
+		}
+	}
+	if b.Allow() {
+		t.Fatal("esperava requisição além da capacidade negada")
+	}
+}
+
+func TestTokenBucketRefillsOverTime(t *testing.T) {
+	b := newTokenBucket(100, 1)
+
+	if !b.Allow() {
+		t.Fatal("esperava primeira requisição permitida")
+	}
+	if b.Allow() {
+		t.Fatal("esperava segunda requisição negada antes de reabastecer")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if !b.Allow() {
+		t.Fatal("esperava requisição permitida após reabastecer tokens")
+	}
+}
+
+func TestRateLimiterPerKeyIsolation(t *testing.T) {
+	rl := newRateLimiter(1, 1)
+
+	if !rl.Allow("device-a") {
+		t.Fatal("esperava primeira requisição de device-a permitida")
+	}
+	if rl.Allow("device-a") {
+		t.Fatal("esperava segunda requisição de device-a negada")
+	}
+	if !rl.Allow("device-b") {
+		t.Fatal("esperava device-b com seu próprio bucket, não afetado por device-a")
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redisStore fala diretamente o protocolo RESP com um servidor Redis,
+// evitando a dependência de um cliente externo. Cada entrada é gravada como
+// uma chave `gpt-gateway:code:<device>` cujo valor é o CodeResponse em JSON,
+// com TTL aplicado via `EX` quando storeTTL > 0.
+type redisStore struct {
+	addr     string
+	password string
+	db       string
+}
+
+const redisKeyPrefix = "gpt-gateway:code:"
+const redisHistoryKeyPrefix = "gpt-gateway:history:"
+
+func newRedisStore(addr, password, db string) (*redisStore, error) {
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+	if db == "" {
+		db = "0"
+	}
+
+	s := &redisStore{addr: addr, password: password, db: db}
+	conn, err := s.dial()
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	conn.Close()
+	return s, nil
+}
+
+func (s *redisStore) dial() (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", s.addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.password != "" {
+		if _, err := redisCommand(conn, "AUTH", s.password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if _, err := redisCommand(conn, "SELECT", s.db); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (s *redisStore) Get(device string) (CodeResponse, bool, error) {
+	conn, err := s.dial()
+	if err != nil {
+		return CodeResponse{}, false, err
+	}
+	defer conn.Close()
+
+	reply, err := redisCommand(conn, "GET", redisKeyPrefix+device)
+	if err != nil {
+		return CodeResponse{}, false, err
+	}
+	if reply == nil {
+		return CodeResponse{}, false, nil
+	}
+
+	var entry CodeResponse
+	if err := json.Unmarshal([]byte(reply.(string)), &entry); err != nil {
+		return CodeResponse{}, false, err
+	}
+	return entry, true, nil
+}
+
+func (s *redisStore) Put(device string, entry CodeResponse) error {
+	conn, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if storeTTL > 0 {
+		_, err = redisCommand(conn, "SET", redisKeyPrefix+device, string(b), "EX", strconv.Itoa(int(storeTTL.Seconds())))
+	} else {
+		_, err = redisCommand(conn, "SET", redisKeyPrefix+device, string(b))
+	}
+	return err
+}
+
+func (s *redisStore) List(filter string) ([]CodeResponse, error) {
+	conn, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	reply, err := redisCommand(conn, "KEYS", redisKeyPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	keys, _ := reply.([]interface{})
+	var out []CodeResponse
+	for _, k := range keys {
+		raw, err := redisCommand(conn, "GET", k.(string))
+		if err != nil || raw == nil {
+			continue
+		}
+		var entry CodeResponse
+		if err := json.Unmarshal([]byte(raw.(string)), &entry); err != nil {
+			continue
+		}
+		if matchesFilter(entry, filter) {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+func (s *redisStore) Delete(device string) error {
+	conn, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = redisCommand(conn, "DEL", redisKeyPrefix+device)
+	if err != nil {
+		return err
+	}
+
+	_, err = redisCommand(conn, "DEL", redisHistoryKeyPrefix+device)
+	return err
+}
+
+// GetHistory e PutHistory guardam o histórico de turnos sob sua própria
+// chave, sem TTL — ao contrário do cache em Get/Put, o histórico não deve
+// expirar sozinho, já que ele é o que permite fechar o loop de uma chamada
+// de ferramenta pendente.
+func (s *redisStore) GetHistory(device string) ([]OpenAIInputItem, error) {
+	conn, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	reply, err := redisCommand(conn, "GET", redisHistoryKeyPrefix+device)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, nil
+	}
+
+	var items []OpenAIInputItem
+	if err := json.Unmarshal([]byte(reply.(string)), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *redisStore) PutHistory(device string, items []OpenAIInputItem) error {
+	conn, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	_, err = redisCommand(conn, "SET", redisHistoryKeyPrefix+device, string(b))
+	return err
+}
+
+// redisCommand envia um comando RESP e decodifica a resposta em string,
+// []interface{}, nil (nil bulk/array) ou int64, conforme o tipo retornado.
+func redisCommand(conn net.Conn, args ...string) (interface{}, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := conn.Write([]byte(sb.String())); err != nil {
+		return nil, err
+	}
+
+	return readRedisReply(bufio.NewReader(conn))
+}
+
+func readRedisReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) == 0 {
+		return nil, fmt.Errorf("redis: resposta vazia")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", line[1:])
+	case ':':
+		n, _ := strconv.ParseInt(line[1:], 10, 64)
+		return n, nil
+	case '$':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := readRedisReply(r)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: resposta inesperada: %q", line)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func readRedisReplyFromString(t *testing.T, raw string) interface{} {
+	t.Helper()
+	reply, err := readRedisReply(bufio.NewReader(strings.NewReader(raw)))
+	if err != nil {
+		t.Fatalf("readRedisReply(%q) retornou erro: %v", raw, err)
+	}
+	return reply
+}
+
+func TestReadRedisReplySimpleString(t *testing.T) {
+	if got := readRedisReplyFromString(t, "+OK\r\n"); got != "OK" {
+		t.Errorf("esperava %q, obtive %q", "OK", got)
+	}
+}
+
+func TestReadRedisReplyInteger(t *testing.T) {
+	if got := readRedisReplyFromString(t, ":42\r\n"); got != int64(42) {
+		t.Errorf("esperava 42, obtive %v", got)
+	}
+}
+
+func TestReadRedisReplyBulkString(t *testing.T) {
+	if got := readRedisReplyFromString(t, "$5\r\nhello\r\n"); got != "hello" {
+		t.Errorf("esperava %q, obtive %q", "hello", got)
+	}
+}
+
+func TestReadRedisReplyNilBulkString(t *testing.T) {
+	if got := readRedisReplyFromString(t, "$-1\r\n"); got != nil {
+		t.Errorf("esperava nil, obtive %v", got)
+	}
+}
+
+func TestReadRedisReplyArray(t *testing.T) {
+	got := readRedisReplyFromString(t, "*2\r\n$3\r\nfoo\r\n$3\r\nbar\r\n")
+	items, ok := got.([]interface{})
+	if !ok || len(items) != 2 || items[0] != "foo" || items[1] != "bar" {
+		t.Errorf("array RESP decodificado incorretamente: %+v", got)
+	}
+}
+
+func TestReadRedisReplyNilArray(t *testing.T) {
+	if got := readRedisReplyFromString(t, "*-1\r\n"); got != nil {
+		t.Errorf("esperava nil, obtive %v", got)
+	}
+}
+
+func TestReadRedisReplyError(t *testing.T) {
+	_, err := readRedisReply(bufio.NewReader(strings.NewReader("-ERR chave inválida\r\n")))
+	if err == nil {
+		t.Fatal("esperava erro para resposta RESP de erro")
+	}
+}
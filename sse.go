@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// sseDoneLine é o marcador de encerramento padrão usado pelas APIs de
+// streaming compatíveis com a OpenAI.
+var sseDoneLine = []byte("data: [DONE]")
+
+// relaySSE copia o corpo de uma resposta SSE upstream linha a linha para w,
+// chamando flush após cada escrita. Só acrescenta o marcador `data: [DONE]`
+// ao final se o upstream ainda não o tiver enviado — alguns upstreams
+// (OpenAI) já terminam o stream com ele, e duplicá-lo confunde clientes que
+// não param no primeiro [DONE].
+func relaySSE(body io.Reader, w io.Writer, flush func()) error {
+	reader := bufio.NewReader(body)
+	sawDone := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if bytes.Equal(bytes.TrimRight(line, "\r\n"), sseDoneLine) {
+				sawDone = true
+			}
+			if _, werr := w.Write(line); werr != nil {
+				return werr
+			}
+			if flush != nil {
+				flush()
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if sawDone {
+		return nil
+	}
+
+	if _, err := w.Write([]byte("data: [DONE]\n\n")); err != nil {
+		return err
+	}
+	if flush != nil {
+		flush()
+	}
+	return nil
+}
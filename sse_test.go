@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRelaySSE(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "upstream já envia o marcador de término",
+			body: "data: {\"choices\":[]}\n\ndata: [DONE]\n\n",
+		},
+		{
+			name: "upstream não envia o marcador de término",
+			body: "data: {\"choices\":[]}\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := relaySSE(strings.NewReader(tt.body), &out, nil); err != nil {
+				t.Fatalf("relaySSE retornou erro: %v", err)
+			}
+
+			got := out.String()
+			if n := strings.Count(got, "data: [DONE]"); n != 1 {
+				t.Fatalf("esperava exatamente um marcador data: [DONE], encontrei %d em %q", n, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// storeTTL é por quanto tempo uma entrada permanece válida após ser gravada.
+// STORE_TTL_SECONDS=0 (padrão) desativa a expiração.
+var storeTTL = func() time.Duration {
+	secs, _ := strconv.Atoi(os.Getenv("STORE_TTL_SECONDS"))
+	return time.Duration(secs) * time.Second
+}()
+
+// Store abstrai o backend de persistência do cache de respostas e do
+// histórico de turnos por dispositivo, permitindo trocar a implementação via
+// STORE_BACKEND sem alterar os handlers HTTP. O histórico precisa viver no
+// mesmo backend do cache: é ele que permite fechar o loop de uma chamada de
+// ferramenta pendente entre reinicializações do processo ou entre réplicas.
+type Store interface {
+	Get(device string) (CodeResponse, bool, error)
+	Put(device string, entry CodeResponse) error
+	List(filter string) ([]CodeResponse, error)
+	Delete(device string) error
+
+	GetHistory(device string) ([]OpenAIInputItem, error)
+	PutHistory(device string, items []OpenAIInputItem) error
+}
+
+// newStoreFromEnv escolhe a implementação de Store conforme STORE_BACKEND
+// (memory, file ou redis). O padrão é "memory", preservando o comportamento
+// original.
+func newStoreFromEnv() (Store, error) {
+	switch backend := os.Getenv("STORE_BACKEND"); backend {
+	case "", "memory":
+		return newMemoryStore(), nil
+	case "file":
+		path := os.Getenv("STORE_FILE_PATH")
+		if path == "" {
+			path = "store.json"
+		}
+		return newFileStore(path)
+	case "redis":
+		return newRedisStore(os.Getenv("REDIS_ADDR"), os.Getenv("REDIS_PASSWORD"), os.Getenv("REDIS_DB"))
+	default:
+		return nil, fmt.Errorf("STORE_BACKEND desconhecido: %s", backend)
+	}
+}
+
+// storedEntry embrulha uma CodeResponse com o instante em que ela expira.
+type storedEntry struct {
+	Entry     CodeResponse `json:"entry"`
+	ExpiresAt time.Time    `json:"expires_at,omitempty"`
+}
+
+func (e storedEntry) expired() bool {
+	return !e.ExpiresAt.IsZero() && time.Now().After(e.ExpiresAt)
+}
+
+func newStoredEntry(entry CodeResponse) storedEntry {
+	se := storedEntry{Entry: entry}
+	if storeTTL > 0 {
+		se.ExpiresAt = time.Now().Add(storeTTL)
+	}
+	return se
+}
+
+// --- memoryStore: comportamento original, agora atrás da interface Store ---
+
+type memoryStore struct {
+	mu      sync.RWMutex
+	data    map[string]storedEntry
+	history map[string][]OpenAIInputItem
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{data: make(map[string]storedEntry), history: make(map[string][]OpenAIInputItem)}
+}
+
+func (s *memoryStore) Get(device string) (CodeResponse, bool, error) {
+	s.mu.RLock()
+	se, ok := s.data[device]
+	s.mu.RUnlock()
+
+	if !ok || se.expired() {
+		return CodeResponse{}, false, nil
+	}
+	return se.Entry, true, nil
+}
+
+func (s *memoryStore) Put(device string, entry CodeResponse) error {
+	s.mu.Lock()
+	s.data[device] = newStoredEntry(entry)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memoryStore) List(filter string) ([]CodeResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []CodeResponse
+	for _, se := range s.data {
+		if se.expired() {
+			continue
+		}
+		if matchesFilter(se.Entry, filter) {
+			out = append(out, se.Entry)
+		}
+	}
+	return out, nil
+}
+
+func (s *memoryStore) Delete(device string) error {
+	s.mu.Lock()
+	delete(s.data, device)
+	delete(s.history, device)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memoryStore) GetHistory(device string) ([]OpenAIInputItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]OpenAIInputItem{}, s.history[device]...), nil
+}
+
+func (s *memoryStore) PutHistory(device string, items []OpenAIInputItem) error {
+	s.mu.Lock()
+	s.history[device] = items
+	s.mu.Unlock()
+	return nil
+}
+
+func matchesFilter(entry CodeResponse, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	return strings.Contains(entry.Keyword, filter) || strings.Contains(entry.DeviceName, filter)
+}
+
+// --- fileStore: JSON em disco com rename atômico, igual ao padrão de
+// access_tokens.json usado pelo ChatGPT-to-API ---
+
+type fileStore struct {
+	mu      sync.Mutex
+	path    string
+	data    map[string]storedEntry
+	history map[string][]OpenAIInputItem
+}
+
+// fileStoreContents é a forma persistida em disco: cache de respostas e
+// histórico de turnos lado a lado, para que ambos sobrevivam a um restart.
+type fileStoreContents struct {
+	Entries map[string]storedEntry       `json:"entries"`
+	History map[string][]OpenAIInputItem `json:"history"`
+}
+
+func newFileStore(path string) (*fileStore, error) {
+	fs := &fileStore{path: path, data: make(map[string]storedEntry), history: make(map[string][]OpenAIInputItem)}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fs, nil
+		}
+		return nil, err
+	}
+	if len(b) == 0 {
+		return fs, nil
+	}
+
+	var contents fileStoreContents
+	if err := json.Unmarshal(b, &contents); err != nil {
+		return nil, err
+	}
+	if contents.Entries != nil {
+		fs.data = contents.Entries
+	}
+	if contents.History != nil {
+		fs.history = contents.History
+	}
+	return fs, nil
+}
+
+// persist grava o conteúdo atual em um arquivo temporário e o renomeia por
+// cima do arquivo final, garantindo que leitores nunca vejam um JSON parcial.
+func (s *fileStore) persist() error {
+	b, err := json.MarshalIndent(fileStoreContents{Entries: s.data, History: s.history}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *fileStore) Get(device string) (CodeResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	se, ok := s.data[device]
+	if !ok || se.expired() {
+		return CodeResponse{}, false, nil
+	}
+	return se.Entry, true, nil
+}
+
+func (s *fileStore) Put(device string, entry CodeResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[device] = newStoredEntry(entry)
+	return s.persist()
+}
+
+func (s *fileStore) List(filter string) ([]CodeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []CodeResponse
+	for _, se := range s.data {
+		if se.expired() {
+			continue
+		}
+		if matchesFilter(se.Entry, filter) {
+			out = append(out, se.Entry)
+		}
+	}
+	return out, nil
+}
+
+func (s *fileStore) Delete(device string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, device)
+	delete(s.history, device)
+	return s.persist()
+}
+
+func (s *fileStore) GetHistory(device string) ([]OpenAIInputItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]OpenAIInputItem{}, s.history[device]...), nil
+}
+
+func (s *fileStore) PutHistory(device string, items []OpenAIInputItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history[device] = items
+	return s.persist()
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestMemoryStoreHistoryRoundTrip(t *testing.T) {
+	s := newMemoryStore()
+
+	items, err := s.GetHistory("device-1")
+	if err != nil {
+		t.Fatalf("GetHistory em device sem histórico retornou erro: %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("esperava histórico vazio, obtive %+v", items)
+	}
+
+	want := []OpenAIInputItem{
+		{Role: "user", Content: "oi"},
+		{Type: "function_call", CallID: "call_1", Name: "f", Arguments: "{}"},
+	}
+	if err := s.PutHistory("device-1", want); err != nil {
+		t.Fatalf("PutHistory retornou erro: %v", err)
+	}
+
+	got, err := s.GetHistory("device-1")
+	if err != nil {
+		t.Fatalf("GetHistory retornou erro: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("esperava %d itens, obtive %d", len(want), len(got))
+	}
+}
+
+func TestMemoryStoreDeleteClearsHistory(t *testing.T) {
+	s := newMemoryStore()
+	_ = s.PutHistory("device-1", []OpenAIInputItem{{Role: "user", Content: "oi"}})
+
+	if err := s.Delete("device-1"); err != nil {
+		t.Fatalf("Delete retornou erro: %v", err)
+	}
+
+	items, _ := s.GetHistory("device-1")
+	if len(items) != 0 {
+		t.Errorf("esperava histórico apagado após Delete, obtive %+v", items)
+	}
+}